@@ -6,6 +6,7 @@ import (
 	"hash"
 	"math"
 	"sort"
+	"sync"
 )
 
 type HashKey uint32
@@ -16,11 +17,24 @@ func (h HashKeyOrder) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
 func (h HashKeyOrder) Less(i, j int) bool { return h[i] < h[j] }
 
 type HashRing struct {
+	mu           sync.RWMutex
 	ring         map[HashKey]string
 	hashProvider func() hash.Hash
 	sortedKeys   []HashKey
 	nodes        []string
 	weights      map[string]int
+
+	// The fields below are only set when the ring is built via
+	// NewWithOptions; see options.go.
+	hash64       func() hash.Hash64
+	replicas     int
+	keyFunc      func(node string, j int) string
+	ring64       map[HashKey64]string
+	sortedKeys64 []HashKey64
+
+	// loads is maintained by Inc/Dec for callers of GetNodeWithLoad that
+	// don't want to track load themselves; see load.go.
+	loads map[string]int64
 }
 
 func New(nodes []string, hashProvider func() hash.Hash) *HashRing {
@@ -63,15 +77,15 @@ func NewWithWeights(weights map[string]int, hashProvider func() hash.Hash) *Hash
 }
 
 func (h *HashRing) generateCircle() {
-	totalWeight := 0
-	for _, node := range h.nodes {
-		if weight, ok := h.weights[node]; ok {
-			totalWeight += weight
-		} else {
-			totalWeight += 1
-		}
+	if h.hash64 != nil {
+		h.generateCircle64()
+		return
 	}
 
+	keyFunc := h.nodeKeyFunc()
+	nodeCount := len(h.nodes)
+	totalWeight := h.totalWeight()
+
 	for _, node := range h.nodes {
 		weight := 1
 
@@ -79,10 +93,10 @@ func (h *HashRing) generateCircle() {
 			weight = h.weights[node]
 		}
 
-		factor := math.Floor(float64(40*len(h.nodes)*weight) / float64(totalWeight))
+		factor := h.vnodeFactor(weight, nodeCount, totalWeight)
 
-		for j := 0; j < int(factor); j++ {
-			nodeKey := fmt.Sprintf("%s-%d", node, j)
+		for j := 0; j < factor; j++ {
+			nodeKey := keyFunc(node, j)
 			bKey := h.hashDigest(nodeKey)
 
 			for i := 0; i < 3; i++ {
@@ -96,8 +110,38 @@ func (h *HashRing) generateCircle() {
 	sort.Sort(HashKeyOrder(h.sortedKeys))
 }
 
+// totalWeight sums the effective weight (defaulting to 1) of every node
+// currently in the ring.
+func (h *HashRing) totalWeight() int {
+	total := 0
+	for _, node := range h.nodes {
+		if w, ok := h.weights[node]; ok {
+			total += w
+		} else {
+			total += 1
+		}
+	}
+	return total
+}
+
+// vnodeFactor returns how many vnodes a node with the given weight gets out
+// of nodeCount nodes sharing totalWeight. generateCircle/generateCircle64
+// and the in-place add and reweight methods all call through this one
+// formula. The count reflects the ring at the time of the call: vnodes added
+// in place are not resized when other nodes later join or leave.
+func (h *HashRing) vnodeFactor(weight, nodeCount, totalWeight int) int {
+	return int(math.Floor(float64(h.replicaCount()*nodeCount*weight) / float64(totalWeight)))
+}
+
 func (h *HashRing) GetNode(stringKey string) (node string, ok bool) {
-	pos, ok := h.GetNodePos(stringKey)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.hash64 != nil {
+		return h.getNode64(stringKey)
+	}
+
+	pos, ok := h.getNodePos(stringKey)
 	if !ok {
 		return "", false
 	}
@@ -105,6 +149,13 @@ func (h *HashRing) GetNode(stringKey string) (node string, ok bool) {
 }
 
 func (h *HashRing) GetNodePos(stringKey string) (pos int, ok bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.getNodePos(stringKey)
+}
+
+func (h *HashRing) getNodePos(stringKey string) (pos int, ok bool) {
 	if len(h.ring) == 0 {
 		return 0, false
 	}
@@ -128,7 +179,14 @@ func (h *HashRing) GenKey(key string) HashKey {
 }
 
 func (h *HashRing) GetNodes(stringKey string, size int) (nodes []string, ok bool) {
-	pos, ok := h.GetNodePos(stringKey)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.hash64 != nil {
+		return h.getNodes64(stringKey, size)
+	}
+
+	pos, ok := h.getNodePos(stringKey)
 	if !ok {
 		return []string{}, false
 	}
@@ -164,6 +222,9 @@ func (h *HashRing) AddWeightedNode(node string, weight int) *HashRing {
 		return h
 	}
 
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
 	for _, eNode := range h.nodes {
 		if eNode == node {
 			return h
@@ -186,12 +247,18 @@ func (h *HashRing) AddWeightedNode(node string, weight int) *HashRing {
 		sortedKeys:   make([]HashKey, 0),
 		nodes:        nodes,
 		weights:      weights,
+		hash64:       h.hash64,
+		replicas:     h.replicas,
+		keyFunc:      h.keyFunc,
 	}
 	hashRing.generateCircle()
 	return hashRing
 }
 
 func (h *HashRing) RemoveNode(node string) *HashRing {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
 	nodes := make([]string, 0)
 	for _, eNode := range h.nodes {
 		if eNode != node {
@@ -212,6 +279,9 @@ func (h *HashRing) RemoveNode(node string) *HashRing {
 		sortedKeys:   make([]HashKey, 0),
 		nodes:        nodes,
 		weights:      weights,
+		hash64:       h.hash64,
+		replicas:     h.replicas,
+		keyFunc:      h.keyFunc,
 	}
 	hashRing.generateCircle()
 	return hashRing
@@ -233,3 +303,208 @@ func hashVal(bKey []byte) HashKey {
 func defaultHashProvider() hash.Hash {
 	return md5.New()
 }
+
+// vnodeKeys returns the ring keys for factor vnodes of node, hashed the
+// same way as generateCircle.
+func (h *HashRing) vnodeKeys(node string, factor int) []HashKey {
+	keyFunc := h.nodeKeyFunc()
+	keys := make([]HashKey, 0, factor*3)
+
+	for j := 0; j < factor; j++ {
+		bKey := h.hashDigest(keyFunc(node, j))
+
+		for i := 0; i < 3; i++ {
+			keys = append(keys, hashVal(bKey[i*4:i*4+4]))
+		}
+	}
+
+	return keys
+}
+
+// vnodeKeys64 is vnodeKeys for rings built with WithHash64.
+func (h *HashRing) vnodeKeys64(node string, factor int) []HashKey64 {
+	keyFunc := h.nodeKeyFunc()
+	keys := make([]HashKey64, 0, factor)
+
+	for j := 0; j < factor; j++ {
+		keys = append(keys, h.genKey64(keyFunc(node, j)))
+	}
+
+	return keys
+}
+
+// putVnodes inserts factor vnodes for node into whichever ring is active.
+func (h *HashRing) putVnodes(node string, factor int) {
+	if h.hash64 != nil {
+		for _, key := range h.vnodeKeys64(node, factor) {
+			h.insertKey64(key, node)
+		}
+		return
+	}
+	for _, key := range h.vnodeKeys(node, factor) {
+		h.insertKey(key, node)
+	}
+}
+
+// dropVnodes removes every vnode mapped to node from whichever ring is
+// active, however many it was given when it was added.
+func (h *HashRing) dropVnodes(node string) {
+	if h.hash64 != nil {
+		for key, eNode := range h.ring64 {
+			if eNode == node {
+				h.removeKey64(key)
+			}
+		}
+		return
+	}
+	for key, eNode := range h.ring {
+		if eNode == node {
+			h.removeKey(key)
+		}
+	}
+}
+
+// hasNode reports whether node is currently in the ring.
+func (h *HashRing) hasNode(node string) bool {
+	for _, eNode := range h.nodes {
+		if eNode == node {
+			return true
+		}
+	}
+	return false
+}
+
+// weightOrDefault returns node's configured weight, or 1 if it has none.
+func (h *HashRing) weightOrDefault(node string) int {
+	if w, ok := h.weights[node]; ok {
+		return w
+	}
+	return 1
+}
+
+// insertKey adds key to the ring and inserts it into sortedKeys in place,
+// preserving order without re-sorting the whole slice.
+func (h *HashRing) insertKey(key HashKey, node string) {
+	if _, exists := h.ring[key]; exists {
+		h.ring[key] = node
+		return
+	}
+
+	pos := sort.Search(len(h.sortedKeys), func(i int) bool { return h.sortedKeys[i] >= key })
+	h.sortedKeys = append(h.sortedKeys, 0)
+	copy(h.sortedKeys[pos+1:], h.sortedKeys[pos:])
+	h.sortedKeys[pos] = key
+	h.ring[key] = node
+}
+
+// removeKey removes key from the ring and from sortedKeys in place.
+func (h *HashRing) removeKey(key HashKey) {
+	pos := sort.Search(len(h.sortedKeys), func(i int) bool { return h.sortedKeys[i] >= key })
+	if pos < len(h.sortedKeys) && h.sortedKeys[pos] == key {
+		h.sortedKeys = append(h.sortedKeys[:pos], h.sortedKeys[pos+1:]...)
+	}
+	delete(h.ring, key)
+}
+
+// AddNodeInPlace adds node to the ring under the write lock, inserting only
+// its own vnodes rather than regenerating the whole circle.
+func (h *HashRing) AddNodeInPlace(node string) {
+	h.AddWeightedNodeInPlace(node, 1)
+}
+
+func (h *HashRing) AddWeightedNodeInPlace(node string, weight int) error {
+	if weight <= 0 {
+		return fmt.Errorf("hashring: weight must be positive, got %d", weight)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.hasNode(node) {
+		return fmt.Errorf("hashring: node %q already exists", node)
+	}
+
+	nodeCount := len(h.nodes) + 1
+	totalWeight := h.totalWeight() + weight
+	factor := h.vnodeFactor(weight, nodeCount, totalWeight)
+
+	h.putVnodes(node, factor)
+
+	h.nodes = append(h.nodes, node)
+	h.weights[node] = weight
+
+	return nil
+}
+
+// UpdateWeight changes node's weight in place, swapping out just its own
+// vnodes for a set sized to the new weight.
+func (h *HashRing) UpdateWeight(node string, weight int) error {
+	if weight <= 0 {
+		return fmt.Errorf("hashring: weight must be positive, got %d", weight)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.hasNode(node) {
+		return fmt.Errorf("hashring: node %q not found", node)
+	}
+
+	nodeCount := len(h.nodes)
+	oldWeight := h.weightOrDefault(node)
+	oldTotalWeight := h.totalWeight()
+
+	newTotalWeight := oldTotalWeight - oldWeight + weight
+	newFactor := h.vnodeFactor(weight, nodeCount, newTotalWeight)
+
+	h.dropVnodes(node)
+	h.putVnodes(node, newFactor)
+
+	h.weights[node] = weight
+
+	return nil
+}
+
+// RemoveNodeInPlace removes node from the ring under the write lock,
+// deleting only its own vnodes.
+func (h *HashRing) RemoveNodeInPlace(node string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.hasNode(node) {
+		return
+	}
+
+	h.dropVnodes(node)
+
+	nodes := make([]string, 0, len(h.nodes))
+	for _, eNode := range h.nodes {
+		if eNode != node {
+			nodes = append(nodes, eNode)
+		}
+	}
+	h.nodes = nodes
+	delete(h.weights, node)
+}
+
+// Nodes returns a snapshot of the node names currently in the ring.
+func (h *HashRing) Nodes() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	nodes := make([]string, len(h.nodes))
+	copy(nodes, h.nodes)
+	return nodes
+}
+
+// Weight returns node's configured weight (1 for a node added without one),
+// or false if node is not in the ring.
+func (h *HashRing) Weight(node string) (int, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.hasNode(node) {
+		return 0, false
+	}
+	return h.weightOrDefault(node), true
+}
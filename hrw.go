@@ -0,0 +1,186 @@
+package hashring
+
+import (
+	"hash"
+	"math"
+	"sort"
+)
+
+// HRW implements Rendezvous (Highest Random Weight) hashing: an alternative
+// to HashRing that needs zero per-vnode memory and moves the minimal set of
+// keys when the node set changes, at the cost of an O(N) scan per lookup.
+// It mirrors the HashRing surface so the two can be swapped interchangeably.
+type HRW struct {
+	nodes        []string
+	weights      map[string]int
+	hashProvider func() hash.Hash
+}
+
+func NewHRW(nodes []string, hashProvider func() hash.Hash) *HRW {
+	if hashProvider == nil {
+		hashProvider = defaultHashProvider
+	}
+
+	return &HRW{
+		nodes:        nodes,
+		weights:      make(map[string]int),
+		hashProvider: hashProvider,
+	}
+}
+
+func NewHRWWithWeights(weights map[string]int, hashProvider func() hash.Hash) *HRW {
+	nodes := make([]string, 0, len(weights))
+	for node := range weights {
+		nodes = append(nodes, node)
+	}
+
+	if hashProvider == nil {
+		hashProvider = defaultHashProvider
+	}
+
+	return &HRW{
+		nodes:        nodes,
+		weights:      weights,
+		hashProvider: hashProvider,
+	}
+}
+
+func (h *HRW) GetNode(stringKey string) (node string, ok bool) {
+	if len(h.nodes) == 0 {
+		return "", false
+	}
+
+	keyHash := h.hash64(stringKey)
+
+	best := h.nodes[0]
+	bestScore := h.score(best, keyHash)
+
+	for _, n := range h.nodes[1:] {
+		if s := h.score(n, keyHash); s > bestScore {
+			bestScore = s
+			best = n
+		}
+	}
+
+	return best, true
+}
+
+func (h *HRW) GetNodes(stringKey string, size int) (nodes []string, ok bool) {
+	if size < 0 || size > len(h.nodes) {
+		return []string{}, false
+	}
+
+	keyHash := h.hash64(stringKey)
+
+	type scoredNode struct {
+		node  string
+		score float64
+	}
+
+	scored := make([]scoredNode, len(h.nodes))
+	for i, n := range h.nodes {
+		scored[i] = scoredNode{node: n, score: h.score(n, keyHash)}
+	}
+
+	sort.Slice(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+
+	resultSlice := make([]string, size)
+	for i := 0; i < size; i++ {
+		resultSlice[i] = scored[i].node
+	}
+
+	return resultSlice, true
+}
+
+func (h *HRW) AddNode(node string) *HRW {
+	return h.AddWeightedNode(node, 1)
+}
+
+func (h *HRW) AddWeightedNode(node string, weight int) *HRW {
+	if weight <= 0 {
+		return h
+	}
+
+	for _, eNode := range h.nodes {
+		if eNode == node {
+			return h
+		}
+	}
+
+	nodes := make([]string, len(h.nodes), len(h.nodes)+1)
+	copy(nodes, h.nodes)
+	nodes = append(nodes, node)
+
+	weights := make(map[string]int)
+	for eNode, eWeight := range h.weights {
+		weights[eNode] = eWeight
+	}
+	weights[node] = weight
+
+	return &HRW{
+		nodes:        nodes,
+		weights:      weights,
+		hashProvider: h.hashProvider,
+	}
+}
+
+func (h *HRW) RemoveNode(node string) *HRW {
+	nodes := make([]string, 0)
+	for _, eNode := range h.nodes {
+		if eNode != node {
+			nodes = append(nodes, eNode)
+		}
+	}
+
+	weights := make(map[string]int)
+	for eNode, eWeight := range h.weights {
+		if eNode != node {
+			weights[eNode] = eWeight
+		}
+	}
+
+	return &HRW{
+		nodes:        nodes,
+		weights:      weights,
+		hashProvider: h.hashProvider,
+	}
+}
+
+// score computes the Jason Resch weighted-HRW score for node against keyHash:
+// score = -weight / ln(u), with u derived from the mixed hash so that
+// increasing a node's weight increases its odds of winning without ever
+// requiring a rebuild of the other nodes' scores.
+func (h *HRW) score(node string, keyHash uint64) float64 {
+	weight := 1
+	if w, ok := h.weights[node]; ok {
+		weight = w
+	}
+
+	w := mix(h.hash64(node) ^ keyHash)
+	u := (float64(w) + 1) / (float64(math.MaxUint64) + 1)
+
+	return -float64(weight) / math.Log(u)
+}
+
+func (h *HRW) hash64(s string) uint64 {
+	hasher := h.hashProvider()
+	hasher.Write([]byte(s))
+	sum := hasher.Sum(nil)
+
+	var v uint64
+	for i := 0; i < 8 && i < len(sum); i++ {
+		v |= uint64(sum[i]) << (8 * uint(i))
+	}
+	return v
+}
+
+// mix is the murmur3-style 64-bit finalizer, used to spread the XOR of two
+// hashes evenly before comparing node scores.
+func mix(a uint64) uint64 {
+	a ^= a >> 33
+	a *= 0xff51afd7ed558ccd
+	a ^= a >> 33
+	a *= 0xc4ceb9fe1a85ec53
+	a ^= a >> 33
+	return a
+}
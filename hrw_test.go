@@ -0,0 +1,63 @@
+package hashring
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHRWAddNodeRemapsAboutOneOverN(t *testing.T) {
+	nodes := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	ring := NewHRW(nodes, nil)
+
+	const numKeys = 20000
+	keys := make([]string, numKeys)
+	before := make([]string, numKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+
+		node, ok := ring.GetNode(keys[i])
+		if !ok {
+			t.Fatalf("GetNode(%q) returned ok=false", keys[i])
+		}
+		before[i] = node
+	}
+
+	newRing := ring.AddNode("k")
+
+	remapped := 0
+	for i, key := range keys {
+		node, ok := newRing.GetNode(key)
+		if !ok {
+			t.Fatalf("GetNode(%q) returned ok=false after AddNode", key)
+		}
+		if node != before[i] {
+			remapped++
+		}
+	}
+
+	// Adding an 11th node to a 10-node ring should remap roughly 1/11 of keys.
+	got := float64(remapped) / float64(numKeys)
+	want := 1.0 / float64(len(nodes)+1)
+	if got < want*0.5 || got > want*1.5 {
+		t.Errorf("remap fraction = %.4f, want close to %.4f", got, want)
+	}
+}
+
+func TestHRWWeightedNodeGetsProportionalShare(t *testing.T) {
+	ring := NewHRWWithWeights(map[string]int{"a": 1, "b": 2}, nil)
+
+	const numKeys = 20000
+	counts := make(map[string]int)
+	for i := 0; i < numKeys; i++ {
+		node, ok := ring.GetNode(fmt.Sprintf("key-%d", i))
+		if !ok {
+			t.Fatalf("GetNode returned ok=false")
+		}
+		counts[node]++
+	}
+
+	ratio := float64(counts["b"]) / float64(counts["a"])
+	if ratio < 1.5 || ratio > 2.5 {
+		t.Errorf("b/a key ratio = %.2f, want close to 2.0 (b has weight 2, a has weight 1)", ratio)
+	}
+}
@@ -0,0 +1,133 @@
+package hashring
+
+import "sort"
+
+// GetNodeWithLoad implements consistent hashing with bounded loads: it walks
+// the ring starting at key's position and skips any node whose current load
+// (from loads) exceeds factor * (totalLoad / N), where N is the number of
+// live nodes, wrapping once around the ring if necessary. This is the
+// standard technique for preventing hot-spotting behind a consistent-hash
+// load balancer.
+func (h *HashRing) GetNodeWithLoad(key string, loads map[string]int64, totalLoad int64, factor float64) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.nodes) == 0 {
+		return "", false
+	}
+
+	avgLoad := factor * float64(totalLoad) / float64(len(h.nodes))
+
+	if h.hash64 != nil {
+		return h.getNodeWithLoad64(key, loads, avgLoad)
+	}
+
+	if len(h.ring) == 0 {
+		return "", false
+	}
+
+	key32 := h.GenKey(key)
+	pos := sort.Search(len(h.sortedKeys), func(i int) bool { return h.sortedKeys[i] > key32 })
+	if pos == len(h.sortedKeys) {
+		pos = 0
+	}
+
+	visited := make(map[string]bool, len(h.nodes))
+
+	for i := 0; i < len(h.sortedKeys); i++ {
+		idx := (pos + i) % len(h.sortedKeys)
+		node := h.ring[h.sortedKeys[idx]]
+
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+
+		if float64(loads[node]) <= avgLoad {
+			return node, true
+		}
+	}
+
+	return "", false
+}
+
+// getNodeWithLoad64 is GetNodeWithLoad for rings built with WithHash64.
+func (h *HashRing) getNodeWithLoad64(key string, loads map[string]int64, avgLoad float64) (string, bool) {
+	if len(h.ring64) == 0 {
+		return "", false
+	}
+
+	key64 := h.genKey64(key)
+	pos := sort.Search(len(h.sortedKeys64), func(i int) bool { return h.sortedKeys64[i] > key64 })
+	if pos == len(h.sortedKeys64) {
+		pos = 0
+	}
+
+	visited := make(map[string]bool, len(h.nodes))
+
+	for i := 0; i < len(h.sortedKeys64); i++ {
+		idx := (pos + i) % len(h.sortedKeys64)
+		node := h.ring64[h.sortedKeys64[idx]]
+
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+
+		if float64(loads[node]) <= avgLoad {
+			return node, true
+		}
+	}
+
+	return "", false
+}
+
+// Inc increments node's internal load counter, so callers that only track
+// load through the ring don't have to thread their own map into
+// GetNodeWithLoad.
+func (h *HashRing) Inc(node string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.loads == nil {
+		h.loads = make(map[string]int64)
+	}
+	h.loads[node]++
+}
+
+// Dec decrements node's internal load counter. It is a no-op if the
+// counter is already zero.
+func (h *HashRing) Dec(node string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.loads[node] > 0 {
+		h.loads[node]--
+	}
+}
+
+// Loads returns a snapshot of the internal load counters maintained by Inc
+// and Dec, suitable for passing to GetNodeWithLoad.
+func (h *HashRing) Loads() map[string]int64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	loads := make(map[string]int64, len(h.loads))
+	for node, load := range h.loads {
+		loads[node] = load
+	}
+	return loads
+}
+
+// TotalLoad returns the sum of the internal load counters maintained by Inc
+// and Dec.
+func (h *HashRing) TotalLoad() int64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var total int64
+	for _, load := range h.loads {
+		total += load
+	}
+	return total
+}
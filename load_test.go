@@ -0,0 +1,76 @@
+package hashring
+
+import "testing"
+
+func TestGetNodeWithLoadSkipsOverloadedNodes(t *testing.T) {
+	ring := New([]string{"a", "b", "c"}, nil)
+
+	key := "some-key"
+	primary, ok := ring.GetNode(key)
+	if !ok {
+		t.Fatalf("GetNode returned ok=false")
+	}
+
+	loads := map[string]int64{primary: 100}
+	node, ok := ring.GetNodeWithLoad(key, loads, 100, 1.25)
+	if !ok {
+		t.Fatalf("GetNodeWithLoad returned ok=false")
+	}
+	if node == primary {
+		t.Errorf("GetNodeWithLoad returned overloaded node %q, want it skipped", primary)
+	}
+}
+
+func TestGetNodeWithLoadAcceptsNodeAtCapacity(t *testing.T) {
+	ring := New([]string{"a", "b", "c"}, nil)
+
+	key := "some-key"
+	primary, ok := ring.GetNode(key)
+	if !ok {
+		t.Fatalf("GetNode returned ok=false")
+	}
+
+	// avgLoad = factor * totalLoad / N = 1.0 * 3 / 3 = 1, and the primary's
+	// own load is exactly 1, so it should still be accepted (<=, not <).
+	loads := map[string]int64{primary: 1}
+	node, ok := ring.GetNodeWithLoad(key, loads, 3, 1.0)
+	if !ok {
+		t.Fatalf("GetNodeWithLoad returned ok=false")
+	}
+	if node != primary {
+		t.Errorf("GetNodeWithLoad = %q, want primary node %q accepted at exactly its capacity", node, primary)
+	}
+}
+
+func TestGetNodeWithLoadEmptyRing(t *testing.T) {
+	ring := New(nil, nil)
+
+	if _, ok := ring.GetNodeWithLoad("key", nil, 0, 1.25); ok {
+		t.Errorf("GetNodeWithLoad on an empty ring returned ok=true")
+	}
+}
+
+func TestIncDecTrackLoad(t *testing.T) {
+	ring := New([]string{"a"}, nil)
+
+	ring.Inc("a")
+	ring.Inc("a")
+	ring.Dec("a")
+
+	if got := ring.Loads()["a"]; got != 1 {
+		t.Errorf("Loads()[\"a\"] = %d, want 1", got)
+	}
+	if got := ring.TotalLoad(); got != 1 {
+		t.Errorf("TotalLoad() = %d, want 1", got)
+	}
+}
+
+func TestDecBelowZeroIsNoop(t *testing.T) {
+	ring := New([]string{"a"}, nil)
+
+	ring.Dec("a")
+
+	if got := ring.Loads()["a"]; got != 0 {
+		t.Errorf("Loads()[\"a\"] = %d, want 0 after Dec on a zero counter", got)
+	}
+}
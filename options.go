@@ -0,0 +1,192 @@
+package hashring
+
+import (
+	"fmt"
+	"hash"
+	"sort"
+)
+
+// HashKey64 is a vnode key for rings built with a 64-bit hash provider (see
+// WithHash64). It parallels HashKey so existing MD5-based rings are
+// unaffected; a HashRing uses one keyspace or the other, never both.
+type HashKey64 uint64
+type HashKey64Order []HashKey64
+
+func (h HashKey64Order) Len() int           { return len(h) }
+func (h HashKey64Order) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h HashKey64Order) Less(i, j int) bool { return h[i] < h[j] }
+
+// Option configures a HashRing built with NewWithOptions.
+type Option func(*HashRing)
+
+// WithHash64 switches the ring to a 64-bit hash provider (e.g. xxhash or
+// murmur3). Instead of slicing one MD5 digest into three 32-bit keys per
+// vnode, each vnode gets a single Sum64 call, matching how hash-based load
+// balancers such as gRPC's xDS ringhash build their ring.
+func WithHash64(hashProvider func() hash.Hash64) Option {
+	return func(h *HashRing) {
+		h.hash64 = hashProvider
+	}
+}
+
+// WithReplicas overrides the default 40x virtual-node multiplier used when
+// generating the circle.
+func WithReplicas(n int) Option {
+	return func(h *HashRing) {
+		h.replicas = n
+	}
+}
+
+// WithKeyFunc overrides how a vnode's label is derived from its node name
+// and index. The default is "<node>-<j>".
+func WithKeyFunc(keyFunc func(node string, j int) string) Option {
+	return func(h *HashRing) {
+		h.keyFunc = keyFunc
+	}
+}
+
+// NewWithOptions builds a HashRing with the given nodes, customized via
+// Option values. With no options it behaves like New.
+func NewWithOptions(nodes []string, opts ...Option) *HashRing {
+	hashRing := &HashRing{
+		ring:         make(map[HashKey]string),
+		hashProvider: defaultHashProvider,
+		sortedKeys:   make([]HashKey, 0),
+		nodes:        nodes,
+		weights:      make(map[string]int),
+	}
+
+	for _, opt := range opts {
+		opt(hashRing)
+	}
+
+	hashRing.generateCircle()
+	return hashRing
+}
+
+func defaultKeyFunc(node string, j int) string {
+	return fmt.Sprintf("%s-%d", node, j)
+}
+
+func (h *HashRing) replicaCount() int {
+	if h.replicas > 0 {
+		return h.replicas
+	}
+	return 40
+}
+
+func (h *HashRing) nodeKeyFunc() func(node string, j int) string {
+	if h.keyFunc != nil {
+		return h.keyFunc
+	}
+	return defaultKeyFunc
+}
+
+// generateCircle64 builds the ring using the configured 64-bit hash
+// provider: one Sum64 call per vnode instead of three 32-bit keys sliced
+// out of an MD5 digest.
+func (h *HashRing) generateCircle64() {
+	h.ring64 = make(map[HashKey64]string)
+	h.sortedKeys64 = make([]HashKey64, 0)
+
+	keyFunc := h.nodeKeyFunc()
+	nodeCount := len(h.nodes)
+	totalWeight := h.totalWeight()
+
+	for _, node := range h.nodes {
+		weight := 1
+		if w, ok := h.weights[node]; ok {
+			weight = w
+		}
+
+		factor := h.vnodeFactor(weight, nodeCount, totalWeight)
+
+		for j := 0; j < factor; j++ {
+			hasher := h.hash64()
+			hasher.Write([]byte(keyFunc(node, j)))
+			key := HashKey64(hasher.Sum64())
+
+			h.ring64[key] = node
+			h.sortedKeys64 = append(h.sortedKeys64, key)
+		}
+	}
+
+	sort.Sort(HashKey64Order(h.sortedKeys64))
+}
+
+func (h *HashRing) genKey64(stringKey string) HashKey64 {
+	hasher := h.hash64()
+	hasher.Write([]byte(stringKey))
+	return HashKey64(hasher.Sum64())
+}
+
+// insertKey64 is insertKey for the 64-bit ring.
+func (h *HashRing) insertKey64(key HashKey64, node string) {
+	if _, exists := h.ring64[key]; exists {
+		h.ring64[key] = node
+		return
+	}
+
+	pos := sort.Search(len(h.sortedKeys64), func(i int) bool { return h.sortedKeys64[i] >= key })
+	h.sortedKeys64 = append(h.sortedKeys64, 0)
+	copy(h.sortedKeys64[pos+1:], h.sortedKeys64[pos:])
+	h.sortedKeys64[pos] = key
+	h.ring64[key] = node
+}
+
+// removeKey64 is removeKey for the 64-bit ring.
+func (h *HashRing) removeKey64(key HashKey64) {
+	pos := sort.Search(len(h.sortedKeys64), func(i int) bool { return h.sortedKeys64[i] >= key })
+	if pos < len(h.sortedKeys64) && h.sortedKeys64[pos] == key {
+		h.sortedKeys64 = append(h.sortedKeys64[:pos], h.sortedKeys64[pos+1:]...)
+	}
+	delete(h.ring64, key)
+}
+
+func (h *HashRing) getNode64(stringKey string) (string, bool) {
+	if len(h.ring64) == 0 {
+		return "", false
+	}
+
+	key := h.genKey64(stringKey)
+	keys := h.sortedKeys64
+	pos := sort.Search(len(keys), func(i int) bool { return keys[i] > key })
+	if pos == len(keys) {
+		pos = 0
+	}
+
+	return h.ring64[keys[pos]], true
+}
+
+func (h *HashRing) getNodes64(stringKey string, size int) ([]string, bool) {
+	if size > len(h.nodes) {
+		return []string{}, false
+	}
+	if len(h.ring64) == 0 {
+		return []string{}, false
+	}
+
+	key := h.genKey64(stringKey)
+	keys := h.sortedKeys64
+	pos := sort.Search(len(keys), func(i int) bool { return keys[i] > key })
+	if pos == len(keys) {
+		pos = 0
+	}
+
+	returnedValues := make(map[string]bool, size)
+	mergedSortedKeys := append(keys[pos:], keys[:pos]...)
+	resultSlice := []string{}
+
+	for _, k := range mergedSortedKeys {
+		val := h.ring64[k]
+		if !returnedValues[val] {
+			returnedValues[val] = true
+			resultSlice = append(resultSlice, val)
+		}
+		if len(returnedValues) == size {
+			break
+		}
+	}
+
+	return resultSlice, len(resultSlice) == size
+}
@@ -0,0 +1,141 @@
+package hashring
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"testing"
+)
+
+func TestNewWithOptionsDefaultsLikeNew(t *testing.T) {
+	nodes := []string{"a", "b", "c"}
+	want := New(nodes, nil)
+	got := NewWithOptions(nodes)
+
+	if len(got.sortedKeys) != len(want.sortedKeys) {
+		t.Fatalf("NewWithOptions produced %d keys, New produced %d", len(got.sortedKeys), len(want.sortedKeys))
+	}
+	for _, key := range []string{"foo", "bar", "baz"} {
+		wantNode, _ := want.GetNode(key)
+		gotNode, _ := got.GetNode(key)
+		if wantNode != gotNode {
+			t.Errorf("GetNode(%q) = %q, want %q (NewWithOptions with no options should match New)", key, gotNode, wantNode)
+		}
+	}
+}
+
+func TestWithReplicasChangesVnodeCount(t *testing.T) {
+	nodes := []string{"a", "b", "c"}
+	low := NewWithOptions(nodes, WithReplicas(5))
+	high := NewWithOptions(nodes, WithReplicas(80))
+
+	if len(high.sortedKeys) <= len(low.sortedKeys) {
+		t.Errorf("WithReplicas(80) produced %d keys, want more than WithReplicas(5)'s %d", len(high.sortedKeys), len(low.sortedKeys))
+	}
+}
+
+func TestWithKeyFuncCustomizesVnodeLabels(t *testing.T) {
+	var seen []string
+	keyFunc := func(node string, j int) string {
+		label := fmt.Sprintf("custom/%s/%d", node, j)
+		seen = append(seen, label)
+		return label
+	}
+
+	NewWithOptions([]string{"a"}, WithReplicas(2), WithKeyFunc(keyFunc))
+
+	want := []string{"custom/a/0", "custom/a/1"}
+	if len(seen) != len(want) {
+		t.Fatalf("keyFunc called %d times, want %d", len(seen), len(want))
+	}
+	for i, label := range want {
+		if seen[i] != label {
+			t.Errorf("keyFunc call %d = %q, want %q", i, seen[i], label)
+		}
+	}
+}
+
+func TestWithHash64UsesSeparate64BitRing(t *testing.T) {
+	ring := NewWithOptions([]string{"a", "b", "c"}, WithHash64(func() hash.Hash64 { return fnv.New64a() }))
+
+	if len(ring.ring) != 0 || len(ring.sortedKeys) != 0 {
+		t.Errorf("WithHash64 ring left the 32-bit ring/sortedKeys non-empty")
+	}
+	if len(ring.ring64) == 0 {
+		t.Fatalf("WithHash64 ring built an empty 64-bit ring")
+	}
+
+	node, ok := ring.GetNode("some-key")
+	if !ok {
+		t.Fatalf("GetNode returned ok=false on a WithHash64 ring")
+	}
+
+	found := false
+	for _, n := range ring.Nodes() {
+		if n == node {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("GetNode returned %q, which is not one of %v", node, ring.Nodes())
+	}
+}
+
+// mixedHash64 layers the package's own murmur3-style avalanche finalizer
+// (see mix in hrw.go) on top of a stdlib hash.Hash64, giving a murmur3-like
+// benchmark target without a third-party dependency, since this tree has no
+// module manifest to pull one in.
+type mixedHash64 struct {
+	hash.Hash64
+}
+
+func (m mixedHash64) Sum64() uint64 {
+	return mix(m.Hash64.Sum64())
+}
+
+func newXXHashStyleHash64() hash.Hash64 {
+	return fnv.New64a()
+}
+
+func newMurmur3StyleHash64() hash.Hash64 {
+	return mixedHash64{fnv.New64a()}
+}
+
+func benchNodeNames(n int) []string {
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf("node-%d", i)
+	}
+	return nodes
+}
+
+func benchmarkGetNode(b *testing.B, ring *HashRing) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ring.GetNode(fmt.Sprintf("key-%d", i))
+	}
+}
+
+func BenchmarkGetNode_MD5_100(b *testing.B)   { benchmarkGetNode(b, New(benchNodeNames(100), nil)) }
+func BenchmarkGetNode_MD5_1000(b *testing.B)  { benchmarkGetNode(b, New(benchNodeNames(1000), nil)) }
+func BenchmarkGetNode_MD5_10000(b *testing.B) { benchmarkGetNode(b, New(benchNodeNames(10000), nil)) }
+
+func BenchmarkGetNode_XXHashStyle_100(b *testing.B) {
+	benchmarkGetNode(b, NewWithOptions(benchNodeNames(100), WithHash64(newXXHashStyleHash64)))
+}
+func BenchmarkGetNode_XXHashStyle_1000(b *testing.B) {
+	benchmarkGetNode(b, NewWithOptions(benchNodeNames(1000), WithHash64(newXXHashStyleHash64)))
+}
+func BenchmarkGetNode_XXHashStyle_10000(b *testing.B) {
+	benchmarkGetNode(b, NewWithOptions(benchNodeNames(10000), WithHash64(newXXHashStyleHash64)))
+}
+
+func BenchmarkGetNode_Murmur3Style_100(b *testing.B) {
+	benchmarkGetNode(b, NewWithOptions(benchNodeNames(100), WithHash64(newMurmur3StyleHash64)))
+}
+func BenchmarkGetNode_Murmur3Style_1000(b *testing.B) {
+	benchmarkGetNode(b, NewWithOptions(benchNodeNames(1000), WithHash64(newMurmur3StyleHash64)))
+}
+func BenchmarkGetNode_Murmur3Style_10000(b *testing.B) {
+	benchmarkGetNode(b, NewWithOptions(benchNodeNames(10000), WithHash64(newMurmur3StyleHash64)))
+}
@@ -0,0 +1,295 @@
+package hashring
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// PartitionRing is an alternative to HashRing that pre-computes a fixed
+// assignment table mapping each of 2^partitionPower partitions to a node,
+// giving O(1) lookups, deterministic memory, and better balance than a
+// vnode ring at the cost of a rebalance step whenever the node set changes.
+type PartitionRing struct {
+	mu             sync.RWMutex
+	nodes          []string
+	weights        map[string]int
+	partitionPower uint
+	assignments    []uint16
+
+	// replicaAssignments holds one assignment row per replica once
+	// Replicas has been called; row 0 always mirrors assignments.
+	replicaAssignments [][]uint16
+}
+
+// PartitionMove describes a single partition changing owners during a
+// Rebalance call.
+type PartitionMove struct {
+	Partition uint32
+	From      string
+	To        string
+}
+
+func NewPartitionRing(nodes []string, weights map[string]int, partitionPower uint) *PartitionRing {
+	p := &PartitionRing{
+		nodes:          nodes,
+		weights:        weights,
+		partitionPower: partitionPower,
+	}
+	p.assignments = assignPartitions(nodes, weights, partitionPower)
+	return p
+}
+
+// GetPartition returns the partition id a key hashes to.
+func (p *PartitionRing) GetPartition(key string) uint32 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return partitionFor(key, p.partitionPower)
+}
+
+// GetNode returns the node assigned to key's partition, or ok=false if the
+// ring has no nodes.
+func (p *PartitionRing) GetNode(key string) (node string, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.nodes) == 0 {
+		return "", false
+	}
+
+	partition := partitionFor(key, p.partitionPower)
+	return p.nodes[p.assignments[partition]], true
+}
+
+// Replicas reconfigures the ring to maintain n independent assignment rows
+// so a key maps to n distinct nodes, for redundancy. Row 0 is always the
+// primary assignment table built by NewPartitionRing/Rebalance; later rows
+// offset each partition's owner index by the row number, which guarantees
+// distinct nodes per partition as long as n does not exceed the node count.
+func (p *PartitionRing) Replicas(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if n > len(p.nodes) {
+		n = len(p.nodes)
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	rows := make([][]uint16, n)
+	rows[0] = p.assignments
+
+	for r := 1; r < n; r++ {
+		row := make([]uint16, len(p.assignments))
+		for partition, primary := range p.assignments {
+			row[partition] = uint16((int(primary) + r) % len(p.nodes))
+		}
+		rows[r] = row
+	}
+
+	p.replicaAssignments = rows
+}
+
+// GetNodes returns up to n distinct nodes for key, as configured by
+// Replicas, or ok=false if the ring has no nodes. Without a prior call to
+// Replicas it returns the single primary node.
+func (p *PartitionRing) GetNodes(key string, n int) (nodes []string, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.nodes) == 0 {
+		return nil, false
+	}
+
+	partition := partitionFor(key, p.partitionPower)
+
+	if len(p.replicaAssignments) == 0 {
+		return []string{p.nodes[p.assignments[partition]]}, true
+	}
+
+	if n > len(p.replicaAssignments) {
+		n = len(p.replicaAssignments)
+	}
+	if n < 0 {
+		n = 0
+	}
+
+	nodes = make([]string, n)
+	for r := 0; r < n; r++ {
+		nodes[r] = p.nodes[p.replicaAssignments[r][partition]]
+	}
+	return nodes, true
+}
+
+// Rebalance reassigns partitions for the given node set and weights,
+// greedily moving partitions off overweight nodes and onto underweight ones
+// until every node is within one partition of its target share, rather than
+// recomputing the whole table from scratch. It returns the partitions that
+// changed owner.
+func (p *PartitionRing) Rebalance(newNodes []string, newWeights map[string]int) []PartitionMove {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	total := len(p.assignments)
+
+	if len(newNodes) == 0 {
+		p.nodes = nil
+		p.weights = nil
+		p.assignments = make([]uint16, total)
+		p.replicaAssignments = nil
+		return nil
+	}
+
+	owner := make([]string, total)
+	for partition, idx := range p.assignments {
+		if int(idx) < len(p.nodes) {
+			owner[partition] = p.nodes[idx]
+		}
+	}
+
+	newIndex := make(map[string]int, len(newNodes))
+	for i, node := range newNodes {
+		newIndex[node] = i
+	}
+
+	totalWeight := 0
+	for _, node := range newNodes {
+		totalWeight += weightOf(newWeights, node)
+	}
+
+	target := make([]int, len(newNodes))
+	for i, node := range newNodes {
+		target[i] = total * weightOf(newWeights, node) / totalWeight
+	}
+
+	current := make([]int, len(newNodes))
+	free := make([]int, 0)
+	for partition, name := range owner {
+		if idx, ok := newIndex[name]; ok {
+			current[idx]++
+		} else {
+			free = append(free, partition)
+		}
+	}
+
+	// Release partitions from overweight nodes into the free pool.
+	for idx, node := range newNodes {
+		for current[idx] > target[idx] {
+			released := false
+			for partition, name := range owner {
+				if name == node {
+					owner[partition] = ""
+					free = append(free, partition)
+					current[idx]--
+					released = true
+					break
+				}
+			}
+			if !released {
+				break
+			}
+		}
+	}
+
+	// Hand free partitions to underweight nodes first.
+	fi := 0
+	for idx, node := range newNodes {
+		for current[idx] < target[idx] && fi < len(free) {
+			owner[free[fi]] = node
+			current[idx]++
+			fi++
+		}
+	}
+
+	// Any partitions left over once every node has reached its rounded-down
+	// target go to whichever node is furthest below its exact share.
+	for ; fi < len(free); fi++ {
+		least := 0
+		leastGap := 0.0
+		for idx, node := range newNodes {
+			share := float64(total*weightOf(newWeights, node)) / float64(totalWeight)
+			if gap := share - float64(current[idx]); idx == 0 || gap > leastGap {
+				least, leastGap = idx, gap
+			}
+		}
+		owner[free[fi]] = newNodes[least]
+		current[least]++
+	}
+
+	newAssignments := make([]uint16, total)
+	var moves []PartitionMove
+	for partition, name := range owner {
+		newAssignments[partition] = uint16(newIndex[name])
+
+		oldName := ""
+		if int(p.assignments[partition]) < len(p.nodes) {
+			oldName = p.nodes[p.assignments[partition]]
+		}
+		if oldName != name {
+			moves = append(moves, PartitionMove{Partition: uint32(partition), From: oldName, To: name})
+		}
+	}
+
+	p.nodes = append([]string(nil), newNodes...)
+	p.weights = newWeights
+	p.assignments = newAssignments
+	p.replicaAssignments = nil
+
+	return moves
+}
+
+func partitionFor(key string, partitionPower uint) uint32 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(key))
+	return uint32(hasher.Sum64() >> (64 - partitionPower))
+}
+
+func weightOf(weights map[string]int, node string) int {
+	if w, ok := weights[node]; ok {
+		return w
+	}
+	return 1
+}
+
+// assignPartitions builds an initial assignment table by repeatedly
+// round-robining through the nodes that still have partitions left to fill,
+// which spreads each node's share evenly across the table instead of in one
+// contiguous block.
+func assignPartitions(nodes []string, weights map[string]int, partitionPower uint) []uint16 {
+	total := 1 << partitionPower
+
+	if len(nodes) == 0 {
+		return make([]uint16, total)
+	}
+
+	totalWeight := 0
+	for _, node := range nodes {
+		totalWeight += weightOf(weights, node)
+	}
+
+	remaining := make([]int, len(nodes))
+	for i, node := range nodes {
+		remaining[i] = total * weightOf(weights, node) / totalWeight
+	}
+
+	assignments := make([]uint16, 0, total)
+	for len(assignments) < total {
+		progressed := false
+		for i := range nodes {
+			if remaining[i] > 0 && len(assignments) < total {
+				assignments = append(assignments, uint16(i))
+				remaining[i]--
+				progressed = true
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	for len(assignments) < total {
+		assignments = append(assignments, uint16(len(assignments)%len(nodes)))
+	}
+
+	return assignments
+}
@@ -0,0 +1,130 @@
+package hashring
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPartitionRingGetNodeIsStableAndCoversAllNodes(t *testing.T) {
+	nodes := []string{"a", "b", "c"}
+	ring := NewPartitionRing(nodes, nil, 8)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		node, ok := ring.GetNode(key)
+		if !ok {
+			t.Fatalf("GetNode(%q) returned ok=false", key)
+		}
+		seen[node] = true
+
+		again, _ := ring.GetNode(key)
+		if again != node {
+			t.Errorf("GetNode(%q) = %q, then %q on repeat call", key, node, again)
+		}
+	}
+
+	if len(seen) != len(nodes) {
+		t.Errorf("GetNode spread across %d nodes, want all %d", len(seen), len(nodes))
+	}
+}
+
+func TestPartitionRingGetNodeEmptyRing(t *testing.T) {
+	ring := NewPartitionRing(nil, nil, 4)
+
+	if _, ok := ring.GetNode("key"); ok {
+		t.Errorf("GetNode on an empty PartitionRing returned ok=true")
+	}
+}
+
+func TestPartitionRingWeightedDistribution(t *testing.T) {
+	weights := map[string]int{"a": 1, "b": 3}
+	ring := NewPartitionRing([]string{"a", "b"}, weights, 10)
+
+	counts := make(map[string]int)
+	for _, node := range ring.assignments {
+		counts[ring.nodes[node]]++
+	}
+
+	total := len(ring.assignments)
+	wantB := float64(total) * 3 / 4
+	gotB := float64(counts["b"])
+	if gotB < wantB*0.9 || gotB > wantB*1.1 {
+		t.Errorf("node b holds %d/%d partitions, want close to %.0f (weight 3 of 4)", counts["b"], total, wantB)
+	}
+}
+
+func TestPartitionRingReplicasGivesDistinctNodesPerPartition(t *testing.T) {
+	ring := NewPartitionRing([]string{"a", "b", "c"}, nil, 6)
+	ring.Replicas(3)
+
+	nodes, ok := ring.GetNodes("some-key", 3)
+	if !ok {
+		t.Fatalf("GetNodes returned ok=false")
+	}
+	if len(nodes) != 3 {
+		t.Fatalf("GetNodes returned %d nodes, want 3", len(nodes))
+	}
+
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		if seen[node] {
+			t.Errorf("GetNodes returned duplicate node %q in %v", node, nodes)
+		}
+		seen[node] = true
+	}
+}
+
+func TestPartitionRingGetNodesWithoutReplicasReturnsPrimary(t *testing.T) {
+	ring := NewPartitionRing([]string{"a", "b"}, nil, 4)
+
+	primary, _ := ring.GetNode("some-key")
+	nodes, ok := ring.GetNodes("some-key", 2)
+	if !ok {
+		t.Fatalf("GetNodes returned ok=false")
+	}
+	if len(nodes) != 1 || nodes[0] != primary {
+		t.Errorf("GetNodes without a prior Replicas call = %v, want [%q]", nodes, primary)
+	}
+}
+
+func TestPartitionRingReplicasClampsZeroAndNegative(t *testing.T) {
+	ring := NewPartitionRing([]string{"a", "b"}, nil, 4)
+
+	ring.Replicas(0)
+	if _, ok := ring.GetNodes("some-key", 0); !ok {
+		t.Errorf("GetNodes returned ok=false after Replicas(0)")
+	}
+
+	ring.Replicas(-1)
+	if _, ok := ring.GetNodes("some-key", -1); !ok {
+		t.Errorf("GetNodes returned ok=false after Replicas(-1)")
+	}
+}
+
+func TestPartitionRingRebalanceMovesMinimalPartitions(t *testing.T) {
+	ring := NewPartitionRing([]string{"a", "b"}, nil, 8)
+
+	moves := ring.Rebalance([]string{"a", "b", "c"}, nil)
+	if len(moves) == 0 {
+		t.Fatalf("Rebalance reported no moves when adding a node")
+	}
+
+	counts := make(map[string]int)
+	for _, node := range ring.assignments {
+		counts[ring.nodes[node]]++
+	}
+	if counts["c"] == 0 {
+		t.Errorf("node c has no partitions after Rebalance")
+	}
+}
+
+func TestPartitionRingRebalanceToEmptyClearsAssignments(t *testing.T) {
+	ring := NewPartitionRing([]string{"a", "b"}, nil, 4)
+
+	ring.Rebalance(nil, nil)
+
+	if _, ok := ring.GetNode("key"); ok {
+		t.Errorf("GetNode returned ok=true after Rebalance to an empty node set")
+	}
+}